@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/operator-manifest-tools/pkg/image"
 	"github.com/operator-framework/operator-manifest-tools/pkg/imageresolver"
 	"github.com/operator-framework/operator-manifest-tools/pkg/pullspec"
@@ -60,6 +61,10 @@ that does not use kustomize and/or contains cluster-ready manifests on disk.
 Set '--version' to supply a semantic version for your bundle if you are creating one
 for the first time or upgrading an existing one.
 
+Set '--format' to 'plain+v0' to generate a plain bundle instead of the default
+'registry+v1' bundle. A plain bundle contains the collected manifests as-is,
+with no CSV, and is the format expected by OLM v1/operator-controller.
+
 If '--output-dir' is set and you wish to build bundle images from that directory,
 either manually update your bundle.Dockerfile or set '--overwrite'.
 
@@ -112,11 +117,21 @@ https://github.com/operator-framework/operator-registry/#manifest-format
 // defaultRootDir is the default root directory in which to generate bundle files.
 const defaultRootDir = "bundle"
 
+// Supported values for the bundleCmd.format flag, which selects the bundle's
+// 'operators.operatorframework.io.bundle.mediatype.v1' metadata value.
+const (
+	bundleFormatRegistryV1 = "registry+v1"
+	bundleFormatPlainV0    = "plain+v0"
+)
+
 // setDefaults sets defaults useful to all modes of this subcommand.
 func (c *bundleCmd) setDefaults() (err error) {
 	if c.packageName, c.layout, err = genutil.GetPackageNameAndLayout(c.packageName); err != nil {
 		return err
 	}
+	if c.format == "" {
+		c.format = bundleFormatRegistryV1
+	}
 	return nil
 }
 
@@ -128,6 +143,12 @@ func (c bundleCmd) validateManifests() (err error) {
 		}
 	}
 
+	switch c.format {
+	case "", bundleFormatRegistryV1, bundleFormatPlainV0:
+	default:
+		return fmt.Errorf("--format must be one of %q or %q, got %q", bundleFormatRegistryV1, bundleFormatPlainV0, c.format)
+	}
+
 	// The three possible usage modes (stdin, inputDir, and legacy dirs) are mutually exclusive
 	// and one must be chosen.
 	isPipeReader := genutil.IsPipeReader()
@@ -177,6 +198,26 @@ func (c bundleCmd) runManifests() (err error) {
 		return err
 	}
 
+	// Run any configured post-processors that contribute manifests to the
+	// collector (e.g. rendering a Helm chart) before CSV generation sees them.
+	if err := c.runCollectorPostProcessors(col); err != nil {
+		return err
+	}
+
+	// The plain+v0 format has no CSV, so manifests are written as-is and
+	// none of the CSV-generation, related-image, or scorecard steps below apply.
+	// Manifest-stage post-processors (pin-images, strip-createdAt, etc.) still run.
+	if c.format == bundleFormatPlainV0 {
+		return c.runManifestsPlain(col)
+	}
+
+	// Capture the previously-generated CSV, if any, before it is overwritten
+	// below, so the FBC fragment can infer this bundle's "replaces" entry.
+	previousCSV, err := c.loadExistingCSV()
+	if err != nil {
+		return fmt.Errorf("error reading existing CSV: %v", err)
+	}
+
 	// If no CSV was initially read, a kustomize base can be used at the default base path.
 	// Only read from kustomizeDir if a base exists so users can still generate a barebones CSV.
 	baseCSVPath := filepath.Join(c.kustomizeDir, "bases", c.packageName+".clusterserviceversion.yaml")
@@ -220,36 +261,93 @@ func (c bundleCmd) runManifests() (err error) {
 	}
 
 	objs := genutil.GetManifestObjects(col, c.extraServiceAccounts)
+	var manifestsDir string
 	if c.stdout {
 		if err := genutil.WriteObjects(stdout, objs...); err != nil {
 			return err
 		}
 	} else {
-		dir := filepath.Join(c.outputDir, bundle.ManifestsDir)
-		if err := genutil.WriteObjectsToFiles(dir, objs...); err != nil {
+		manifestsDir = filepath.Join(c.outputDir, bundle.ManifestsDir)
+		if err := genutil.WriteObjectsToFiles(manifestsDir, objs...); err != nil {
 			return err
 		}
 	}
 
-	// Pin images to digests if enabled
-	if c.useImageDigests {
-		c.println("pinning image versions to digests instead of tags")
-		if err := c.pinImages(filepath.Join(c.outputDir, "manifests")); err != nil {
+	// Run any configured post-processors against the on-disk manifests, e.g.
+	// pinning image digests, stripping createdAt, or injecting labels.
+	if manifestsDir != "" {
+		if err := c.runManifestPostProcessors(manifestsDir); err != nil {
 			return err
 		}
 	}
 
+	// Merge in any external scorecard config files and built-in suites before writing.
+	scorecardConfig, err := c.mergeScorecardConfig(col.ScorecardConfig)
+	if err != nil {
+		return fmt.Errorf("error composing bundle scorecard config: %v", err)
+	}
+
 	// Write the scorecard config if it was passed.
-	if err := writeScorecardConfig(c.outputDir, col.ScorecardConfig); err != nil {
+	if err := writeScorecardConfig(c.outputDir, scorecardConfig); err != nil {
 		return fmt.Errorf("error writing bundle scorecard config: %v", err)
 	}
 
+	// Generate a File-Based Catalog fragment for this bundle, if requested.
+	if err := c.runFBC(col, previousCSV); err != nil {
+		return fmt.Errorf("error generating File-Based Catalog fragment: %v", err)
+	}
+
 	c.println("Bundle manifests generated successfully in", c.outputDir)
 
 	return nil
 
 }
 
+// loadExistingCSV reads the CSV already on disk at c.outputDir, if one
+// exists, e.g. from a prior run of this command. Returns nil if none exists.
+func (c bundleCmd) loadExistingCSV() (*v1alpha1.ClusterServiceVersion, error) {
+	if c.stdout {
+		return nil, nil
+	}
+	csvPath := filepath.Join(c.outputDir, bundle.ManifestsDir, c.packageName+".clusterserviceversion.yaml")
+	if !genutil.IsExist(csvPath) {
+		return nil, nil
+	}
+	return bases.ClusterServiceVersion{BasePath: csvPath}.GetBase()
+}
+
+// runManifestsPlain writes the plain+v0 bundle: all manifests collected in col
+// are written directly to bundle/manifests/ with no CSV, related-image, or
+// scorecard processing.
+func (c bundleCmd) runManifestsPlain(col *collector.Manifests) error {
+	objs := genutil.GetManifestObjects(col, c.extraServiceAccounts)
+
+	var manifestsDir string
+	if c.stdout {
+		stdout := genutil.NewMultiManifestWriter(os.Stdout)
+		if err := genutil.WriteObjects(stdout, objs...); err != nil {
+			return err
+		}
+	} else {
+		manifestsDir = filepath.Join(c.outputDir, bundle.ManifestsDir)
+		if err := genutil.WriteObjectsToFiles(manifestsDir, objs...); err != nil {
+			return err
+		}
+	}
+
+	// Plain bundles are the artifacts OLM v1/operator-controller users most
+	// want pinned, so manifest-stage post-processors still apply here.
+	if manifestsDir != "" {
+		if err := c.runManifestPostProcessors(manifestsDir); err != nil {
+			return err
+		}
+	}
+
+	c.println("Bundle manifests generated successfully in", c.outputDir)
+
+	return nil
+}
+
 // writeScorecardConfig writes cfg to dir at the hard-coded config path 'config.yaml'.
 func writeScorecardConfig(dir string, cfg v1alpha3.Configuration) error {
 	// Skip writing if config is empty.
@@ -304,23 +402,67 @@ func (c bundleCmd) runMetadata() error {
 		DefaultChannel:       c.defaultChannel,
 		OtherLabels:          metricsannotations.MakeBundleMetadataLabels(c.layout),
 		IsScoreConfigPresent: genutil.IsExist(scorecardConfigPath),
+		MediaType:            c.format,
 	}
 
 	return bundleMetadata.GenerateMetadata()
 }
 
+// resolverKindForFlag maps the --image-resolver flag value to the
+// imageresolver.Resolver kind understood by imageresolver.GetResolver.
+// "none" has no corresponding kind: it means "no online resolver", which
+// pinImages handles itself by skipping GetResolver entirely.
+func resolverKindForFlag(flag string) (string, error) {
+	switch flag {
+	case "", "crane":
+		return imageresolver.ResolverCrane, nil
+	case "skopeo":
+		return imageresolver.ResolverSkopeo, nil
+	case "none":
+		return "", nil
+	default:
+		return "", fmt.Errorf("--image-resolver must be one of \"crane\", \"skopeo\", or \"none\", got %q", flag)
+	}
+}
+
 // pinImages is used to replace all image tags in the given manifests with digests
 func (c bundleCmd) pinImages(manifestPath string) error {
 	manifests, err := pullspec.FromDirectory(manifestPath, nil)
 	if err != nil {
 		return err
 	}
-	resolverArgs := make(map[string]string)
-	resolverArgs["usedefault"] = "true"
-	resolver, err := imageresolver.GetResolver(imageresolver.ResolverCrane, resolverArgs)
-	if err != nil {
-		return err
+
+	// --image-resolver=none means only the offline --image-digest-map may be
+	// consulted; resolving anything not in the map is then an error.
+	var fallback imageresolver.Resolver
+	if c.imageResolver != "none" {
+		resolverKind, err := resolverKindForFlag(c.imageResolver)
+		if err != nil {
+			return err
+		}
+
+		resolverArgs := make(map[string]string)
+		resolverArgs["usedefault"] = "true"
+		if c.imageAuthFile != "" {
+			resolverArgs["authfile"] = c.imageAuthFile
+		}
+		fallback, err = imageresolver.GetResolver(resolverKind, resolverArgs)
+		if err != nil {
+			return err
+		}
+	} else if c.imageDigestMap == "" {
+		return errors.New("--image-resolver=none requires --image-digest-map to resolve any images")
+	}
+
+	resolver := fallback
+	if c.imageDigestMap != "" {
+		overrides, err := loadImageDigestMap(c.imageDigestMap)
+		if err != nil {
+			return fmt.Errorf("error reading --image-digest-map: %v", err)
+		}
+		resolver = newMapResolver(overrides, fallback)
 	}
+
 	if err := image.Pin(resolver, manifests); err != nil {
 		return err
 	}
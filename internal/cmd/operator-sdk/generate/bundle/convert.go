@@ -0,0 +1,194 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	genutil "github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/internal"
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+// annotationChannels and annotationDefaultChannel are the well-known bundle
+// annotation keys that carry a bundle's channel membership, read here so a
+// converted bundle's annotations.yaml matches the source it was converted from.
+const (
+	annotationChannels       = "operators.operatorframework.io.bundle.channels.v1"
+	annotationDefaultChannel = "operators.operatorframework.io.bundle.channel.default.v1"
+)
+
+// runConvert reads an existing registry+v1 bundle from c.inputDir and writes
+// a plain+v0 bundle to c.outputDir, materializing the Deployment(s),
+// ServiceAccount(s), and RBAC described by the CSV's install strategy (and
+// carrying over the bundle's CRDs) as standalone manifests. This is the
+// implementation behind 'generate bundle convert --from=registry+v1 --to=plain+v0'.
+func (c bundleCmd) runConvert() error {
+	if c.inputDir == "" {
+		return errors.New("--input-dir must be set to the registry+v1 bundle to convert")
+	}
+	if !c.stdout && c.outputDir == "" {
+		c.outputDir = defaultRootDir
+	}
+
+	c.println("Converting registry+v1 bundle", c.inputDir, "to plain+v0")
+
+	col := &collector.Manifests{}
+	if err := col.UpdateFromDir(c.inputDir); err != nil {
+		return fmt.Errorf("error reading registry+v1 bundle: %v", err)
+	}
+	if len(col.ClusterServiceVersions) == 0 {
+		return fmt.Errorf("no ClusterServiceVersion found in %q", c.inputDir)
+	}
+	csv := col.ClusterServiceVersions[0]
+
+	// Derive the package name, version, and channels from the source bundle
+	// rather than from c's flags/project defaults, so the migrated bundle's
+	// annotations.yaml matches the registry+v1 bundle it was converted from.
+	c.version = csv.Spec.Version.String()
+	if packageName := strings.TrimSuffix(csv.Name, ".v"+c.version); packageName != csv.Name {
+		c.packageName = packageName
+	}
+	if channels, defaultChannel := readSourceChannels(c.inputDir); len(channels) > 0 {
+		c.channels = channels
+		c.defaultChannel = defaultChannel
+	}
+
+	// c.inputDir named the source registry+v1 bundle we just read; clear it so
+	// runMetadata below writes fresh plain+v0 metadata to c.outputDir instead
+	// of finding the source bundle's existing metadata and skipping.
+	c.inputDir = ""
+
+	objs := make([]runtime.Object, 0)
+	for i := range col.V1CustomResourceDefinitions {
+		objs = append(objs, &col.V1CustomResourceDefinitions[i])
+	}
+
+	strategy := csv.Spec.InstallStrategy.StrategySpec
+	for _, depSpec := range strategy.DeploymentSpecs {
+		objs = append(objs, &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   depSpec.Name,
+				Labels: depSpec.Label,
+			},
+			Spec: depSpec.Spec,
+		})
+	}
+
+	// Permissions and ClusterPermissions commonly reuse the same
+	// ServiceAccountName across entries, so only emit each once.
+	seenServiceAccounts := map[string]bool{}
+	addServiceAccount := func(name string) {
+		if seenServiceAccounts[name] {
+			return
+		}
+		seenServiceAccounts[name] = true
+		objs = append(objs, &corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		})
+	}
+
+	for _, perm := range strategy.Permissions {
+		addServiceAccount(perm.ServiceAccountName)
+		objs = append(objs, &rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+			ObjectMeta: metav1.ObjectMeta{Name: perm.ServiceAccountName},
+			Rules:      perm.Rules,
+		})
+		objs = append(objs, &rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: perm.ServiceAccountName},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "Role",
+				Name:     perm.ServiceAccountName,
+			},
+			Subjects: []rbacv1.Subject{
+				{Kind: "ServiceAccount", Name: perm.ServiceAccountName},
+			},
+		})
+	}
+
+	for _, perm := range strategy.ClusterPermissions {
+		addServiceAccount(perm.ServiceAccountName)
+		objs = append(objs, &rbacv1.ClusterRole{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+			ObjectMeta: metav1.ObjectMeta{Name: perm.ServiceAccountName},
+			Rules:      perm.Rules,
+		})
+		objs = append(objs, &rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: perm.ServiceAccountName},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "ClusterRole",
+				Name:     perm.ServiceAccountName,
+			},
+			Subjects: []rbacv1.Subject{
+				{Kind: "ServiceAccount", Name: perm.ServiceAccountName},
+			},
+		})
+	}
+
+	dir := filepath.Join(c.outputDir, "manifests")
+	if err := genutil.WriteObjectsToFiles(dir, objs...); err != nil {
+		return err
+	}
+
+	c.format = bundleFormatPlainV0
+	if err := c.runMetadata(); err != nil {
+		return err
+	}
+
+	c.println("Plain bundle written successfully to", c.outputDir)
+
+	return nil
+}
+
+// readSourceChannels reads the channel membership of the registry+v1 bundle
+// at bundleDir from its metadata/annotations.yaml, returning nil/"" if the
+// file or either annotation is absent.
+func readSourceChannels(bundleDir string) (channels []string, defaultChannel string) {
+	b, err := os.ReadFile(filepath.Join(bundleDir, "metadata", "annotations.yaml"))
+	if err != nil {
+		return nil, ""
+	}
+
+	parsed := struct {
+		Annotations map[string]string `json:"annotations"`
+	}{}
+	if err := yaml.Unmarshal(b, &parsed); err != nil {
+		return nil, ""
+	}
+
+	if v := parsed.Annotations[annotationChannels]; v != "" {
+		channels = strings.Split(v, ",")
+	}
+	defaultChannel = parsed.Annotations[annotationDefaultChannel]
+
+	return channels, defaultChannel
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+// fbcFileName is the name of the generated File-Based Catalog fragment.
+const fbcFileName = "catalog.yaml"
+
+// runFBC generates a File-Based Catalog fragment describing this bundle's
+// package, channels, and bundle entry into c.fbcOutputDir. It is a no-op if
+// --fbc-output-dir was not set. The fragment is directly consumable by
+// 'opm render'/'opm validate', or may be appended to an existing catalog.
+// previousCSV, if non-nil, is the CSV this bundle's version is replacing,
+// as found on disk at c.outputDir before this run overwrote it.
+func (c bundleCmd) runFBC(col *collector.Manifests, previousCSV *v1alpha1.ClusterServiceVersion) error {
+	if c.fbcOutputDir == "" {
+		return nil
+	}
+	c.println("Generating File-Based Catalog fragment")
+
+	if len(col.ClusterServiceVersions) == 0 {
+		return fmt.Errorf("cannot generate a File-Based Catalog fragment for a bundle with no ClusterServiceVersion")
+	}
+	csv := col.ClusterServiceVersions[0]
+
+	bundleName := c.packageName + "." + "v" + c.version
+	props := bundleProperties(c.packageName, c.version, csv)
+
+	bundleImage := c.fbcBundleImage
+	if bundleImage == "" {
+		bundleImage = fmt.Sprintf("%s:v%s", c.packageName, c.version)
+		c.println("--fbc-bundle-image not set, using", bundleImage, "as a placeholder bundle image reference")
+	}
+
+	cfg := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{
+				Schema:         declcfg.SchemaPackage,
+				Name:           c.packageName,
+				DefaultChannel: c.defaultChannel,
+			},
+		},
+		Bundles: []declcfg.Bundle{
+			{
+				Schema:     declcfg.SchemaBundle,
+				Name:       bundleName,
+				Package:    c.packageName,
+				Image:      bundleImage,
+				Properties: props,
+			},
+		},
+	}
+
+	// Regenerating the same version in place leaves previousCSV pointing at
+	// this very bundle; a channel entry can't replace itself, so skip it.
+	var replaces string
+	if previousCSV != nil {
+		if candidate := c.packageName + ".v" + previousCSV.Spec.Version.String(); candidate != bundleName {
+			replaces = candidate
+		}
+	}
+
+	for _, channel := range c.channels {
+		cfg.Channels = append(cfg.Channels, declcfg.Channel{
+			Schema:  declcfg.SchemaChannel,
+			Name:    channel,
+			Package: c.packageName,
+			Entries: []declcfg.ChannelEntry{
+				{
+					Name:     bundleName,
+					Replaces: replaces,
+				},
+			},
+		})
+	}
+
+	if err := os.MkdirAll(c.fbcOutputDir, 0755); err != nil {
+		return err
+	}
+	fbcPath := filepath.Join(c.fbcOutputDir, fbcFileName)
+	f, err := os.Create(fbcPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := declcfg.WriteYAML(cfg, f); err != nil {
+		return fmt.Errorf("error writing File-Based Catalog fragment: %v", err)
+	}
+
+	c.println("File-Based Catalog fragment generated successfully in", c.fbcOutputDir)
+
+	return nil
+}
+
+// bundleProperties extracts the olm.package and olm.gvk(.required) properties
+// for csv's owned and required CRDs, to embed in the bundle's catalog entry.
+func bundleProperties(packageName, version string, csv v1alpha1.ClusterServiceVersion) []property.Property {
+	props := []property.Property{
+		property.MustBuildPackage(packageName, version),
+	}
+
+	for _, owned := range csv.Spec.CustomResourceDefinitions.Owned {
+		props = append(props, property.MustBuildGVK(crdGroup(owned.Name), owned.Version, owned.Kind))
+	}
+	for _, required := range csv.Spec.CustomResourceDefinitions.Required {
+		props = append(props, property.MustBuildGVKRequired(crdGroup(required.Name), required.Version, required.Kind))
+	}
+
+	return props
+}
+
+// crdGroup returns the API group portion of a CRDDescription's plural.group
+// Name, e.g. "foos.cache.example.com" -> "cache.example.com".
+func crdGroup(crdName string) string {
+	if i := strings.Index(crdName, "."); i >= 0 {
+		return crdName[i+1:]
+	}
+	return crdName
+}
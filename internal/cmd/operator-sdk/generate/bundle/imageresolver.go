@@ -0,0 +1,68 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-manifest-tools/pkg/imageresolver"
+)
+
+// loadImageDigestMap reads a YAML or JSON file at path containing a mapping
+// of image pullspec to resolved digest, e.g.:
+//
+//	quay.io/example/operand:1.0: sha256:2cf3a...
+//
+// and returns it as a Go map. sigs.k8s.io/yaml accepts both YAML and JSON.
+func loadImageDigestMap(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	digestMap := map[string]string{}
+	if err := yaml.Unmarshal(b, &digestMap); err != nil {
+		return nil, err
+	}
+	return digestMap, nil
+}
+
+// mapResolver resolves a pullspec to a digest using a pre-populated offline
+// map, falling back to another resolver for pullspecs the map does not cover.
+// This lets bundle generation pin images without a registry round-trip when
+// the digests are already known, e.g. from a prior CI build.
+type mapResolver struct {
+	digests  map[string]string
+	fallback imageresolver.Resolver
+}
+
+// newMapResolver returns an imageresolver.Resolver backed by digests, falling
+// back to fallback for any pullspec not present in digests.
+func newMapResolver(digests map[string]string, fallback imageresolver.Resolver) imageresolver.Resolver {
+	return &mapResolver{digests: digests, fallback: fallback}
+}
+
+// ResolveImageReference implements imageresolver.Resolver.
+func (r *mapResolver) ResolveImageReference(pullspec string) (string, error) {
+	if digest, ok := r.digests[pullspec]; ok {
+		return digest, nil
+	}
+	if r.fallback == nil {
+		return "", fmt.Errorf("no digest override for %q and no fallback resolver configured", pullspec)
+	}
+	return r.fallback.ResolveImageReference(pullspec)
+}
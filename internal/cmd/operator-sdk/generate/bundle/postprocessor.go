@@ -0,0 +1,255 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/generate/collector"
+)
+
+// collectorPostProcessor is a post-processor that contributes manifests to
+// the collector before CSV generation, e.g. rendering a Helm chart.
+type collectorPostProcessor interface {
+	processCollector(col *collector.Manifests) error
+}
+
+// manifestPostProcessor is a post-processor that runs against the on-disk
+// bundle manifests directory after the CSV and other manifests are written.
+type manifestPostProcessor interface {
+	processManifests(manifestsDir string) error
+}
+
+// parsePostProcessorSpec splits a "--post-processor" value of the form
+// "name[:key=val,key2=val2]" into its name and argument map.
+func parsePostProcessorSpec(spec string) (name string, args map[string]string) {
+	name, rest, hasArgs := strings.Cut(spec, ":")
+	args = map[string]string{}
+	if !hasArgs {
+		return name, args
+	}
+	for _, pair := range strings.Split(rest, ",") {
+		k, v, _ := strings.Cut(pair, "=")
+		args[k] = v
+	}
+	return name, args
+}
+
+// buildPostProcessor constructs the named built-in post-processor, or an
+// exec:<path> processor that shells out to a user-provided binary. exec:
+// is checked against the raw spec, not the parsed name, since the path it
+// carries may itself contain the "name:args" separator.
+func (c bundleCmd) buildPostProcessor(spec string) (interface{}, error) {
+	if path, isExec := strings.CutPrefix(spec, "exec:"); isExec {
+		return execPostProcessor{path: path}, nil
+	}
+
+	name, args := parsePostProcessorSpec(spec)
+
+	switch name {
+	case "pin-images":
+		return pinImagesPostProcessor{cmd: c}, nil
+	case "strip-createdAt":
+		return stripCreatedAtPostProcessor{}, nil
+	case "inject-labels":
+		return injectLabelsPostProcessor{labels: args}, nil
+	case "helm-render":
+		chartDir := args["chart"]
+		if chartDir == "" {
+			return nil, fmt.Errorf("post-processor %q requires a chart=<dir> argument", name)
+		}
+		return helmRenderPostProcessor{chartDir: chartDir}, nil
+	default:
+		return nil, fmt.Errorf("unknown --post-processor %q", name)
+	}
+}
+
+// postProcessorSpecs returns the configured post-processor specs, with
+// --use-image-digests folded in as a "pin-images" spec for backwards
+// compatibility with the flag it replaces.
+func (c bundleCmd) postProcessorSpecs() []string {
+	specs := append([]string{}, c.postProcessors...)
+	if c.useImageDigests {
+		specs = append(specs, "pin-images")
+	}
+	return specs
+}
+
+// runCollectorPostProcessors runs the collectorPostProcessor stage of every
+// configured post-processor, in flag order, against col.
+func (c bundleCmd) runCollectorPostProcessors(col *collector.Manifests) error {
+	for _, spec := range c.postProcessorSpecs() {
+		pp, err := c.buildPostProcessor(spec)
+		if err != nil {
+			return err
+		}
+		cpp, ok := pp.(collectorPostProcessor)
+		if !ok {
+			continue
+		}
+		if err := cpp.processCollector(col); err != nil {
+			return fmt.Errorf("post-processor %q: %v", spec, err)
+		}
+	}
+	return nil
+}
+
+// runManifestPostProcessors runs the manifestPostProcessor stage of every
+// configured post-processor, in flag order, against manifestsDir.
+func (c bundleCmd) runManifestPostProcessors(manifestsDir string) error {
+	for _, spec := range c.postProcessorSpecs() {
+		pp, err := c.buildPostProcessor(spec)
+		if err != nil {
+			return err
+		}
+		mpp, ok := pp.(manifestPostProcessor)
+		if !ok {
+			continue
+		}
+		c.println("running post-processor", spec)
+		if err := mpp.processManifests(manifestsDir); err != nil {
+			return fmt.Errorf("post-processor %q: %v", spec, err)
+		}
+	}
+	return nil
+}
+
+// pinImagesPostProcessor resolves image tags to digests, reusing bundleCmd.pinImages.
+type pinImagesPostProcessor struct {
+	cmd bundleCmd
+}
+
+func (p pinImagesPostProcessor) processManifests(manifestsDir string) error {
+	return p.cmd.pinImages(manifestsDir)
+}
+
+// stripCreatedAtPostProcessor removes the "createdAt" annotation that CSV
+// generation stamps onto the CSV, useful for producing reproducible bundles.
+type stripCreatedAtPostProcessor struct{}
+
+func (stripCreatedAtPostProcessor) processManifests(manifestsDir string) error {
+	return walkYAMLFiles(manifestsDir, func(path string, obj map[string]interface{}) (map[string]interface{}, error) {
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		if metadata == nil {
+			return obj, nil
+		}
+		annotations, _ := metadata["annotations"].(map[string]interface{})
+		if annotations == nil {
+			return obj, nil
+		}
+		delete(annotations, "createdAt")
+		return obj, nil
+	})
+}
+
+// injectLabelsPostProcessor adds a fixed set of labels to every manifest's
+// metadata.labels, as configured via the post-processor's key=val arguments.
+type injectLabelsPostProcessor struct {
+	labels map[string]string
+}
+
+func (p injectLabelsPostProcessor) processManifests(manifestsDir string) error {
+	return walkYAMLFiles(manifestsDir, func(path string, obj map[string]interface{}) (map[string]interface{}, error) {
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		if metadata == nil {
+			return obj, nil
+		}
+		labels, _ := metadata["labels"].(map[string]interface{})
+		if labels == nil {
+			labels = map[string]interface{}{}
+		}
+		for k, v := range p.labels {
+			labels[k] = v
+		}
+		metadata["labels"] = labels
+		return obj, nil
+	})
+}
+
+// helmRenderPostProcessor renders a Helm chart and merges its output
+// manifests into the collector before CSV generation.
+type helmRenderPostProcessor struct {
+	chartDir string
+}
+
+func (p helmRenderPostProcessor) processCollector(col *collector.Manifests) error {
+	cmd := exec.Command("helm", "template", p.chartDir)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error rendering Helm chart %q: %v: %s", p.chartDir, err, out.String())
+	}
+	return col.UpdateFromReader(bytes.NewReader(out.Bytes()))
+}
+
+// execPostProcessor shells out to a user-provided binary, passing the bundle
+// manifests directory as both an argument and over stdin.
+type execPostProcessor struct {
+	path string
+}
+
+func (p execPostProcessor) processManifests(manifestsDir string) error {
+	cmd := exec.Command(p.path, manifestsDir)
+	cmd.Stdin = strings.NewReader(manifestsDir)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// walkYAMLFiles reads every *.yaml file in dir, applies fn to its parsed
+// content, and writes the result back if fn returned a non-nil object.
+func walkYAMLFiles(dir string, fn func(path string, obj map[string]interface{}) (map[string]interface{}, error)) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal(b, &obj); err != nil {
+			return err
+		}
+		obj, err = fn(path, obj)
+		if err != nil {
+			return err
+		}
+		if obj == nil {
+			continue
+		}
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, out, 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/operator-framework/operator-sdk/internal/scorecard"
+)
+
+// mergeScorecardConfig composes the final scorecard configuration for this
+// bundle out of three sources, in order: cfg (collected from input manifests),
+// any files in c.scorecardConfigPaths, and any built-in suites named in
+// c.scorecardSuite. Tests are de-duplicated by their label selector so that
+// applying the same suite or file more than once is a no-op.
+func (c bundleCmd) mergeScorecardConfig(cfg v1alpha3.Configuration) (v1alpha3.Configuration, error) {
+	seen := map[string]bool{}
+	for _, stage := range cfg.Stages {
+		for _, test := range stage.Tests {
+			seen[testKey(test)] = true
+		}
+	}
+
+	appendStage := func(stage v1alpha3.StageConfiguration) {
+		deduped := make([]v1alpha3.TestConfiguration, 0, len(stage.Tests))
+		for _, test := range stage.Tests {
+			key := testKey(test)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			deduped = append(deduped, test)
+		}
+		if len(deduped) == 0 {
+			return
+		}
+		stage.Tests = deduped
+		cfg.Stages = append(cfg.Stages, stage)
+	}
+
+	for _, path := range c.scorecardConfigPaths {
+		extra, err := loadScorecardConfig(path)
+		if err != nil {
+			return cfg, fmt.Errorf("error reading --scorecard-config %q: %v", path, err)
+		}
+		for _, stage := range extra.Stages {
+			appendStage(stage)
+		}
+	}
+
+	for _, suite := range splitAndTrim(c.scorecardSuite) {
+		stage, err := scorecard.BuiltinStage(suite)
+		if err != nil {
+			return cfg, fmt.Errorf("error resolving --scorecard-suite %q: %v", suite, err)
+		}
+		appendStage(stage)
+	}
+
+	// writeScorecardConfig skips a config with no name, so give a config
+	// composed only from files and suites the default identity.
+	if cfg.Metadata.Name == "" && len(cfg.Stages) > 0 {
+		cfg.APIVersion = "scorecard.operatorframework.io/v1alpha3"
+		cfg.Kind = "Configuration"
+		cfg.Metadata.Name = "config"
+	}
+
+	return cfg, nil
+}
+
+// loadScorecardConfig reads and parses a v1alpha3.Configuration file.
+func loadScorecardConfig(path string) (v1alpha3.Configuration, error) {
+	cfg := v1alpha3.Configuration{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// testKey returns a stable, order-independent key for a test's label
+// selector, used to de-duplicate tests across merged configurations.
+func testKey(test v1alpha3.TestConfiguration) string {
+	labels := make([]string, 0, len(test.Labels))
+	for k, v := range test.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ",")
+}
+
+// splitAndTrim splits a comma-separated flag value into its trimmed,
+// non-empty elements.
+func splitAndTrim(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+const (
+	// DefaultConfigDir is the directory, relative to a bundle's root, that
+	// holds the scorecard config file.
+	DefaultConfigDir = "tests/scorecard"
+	// ConfigFileName is the name of the scorecard config file within DefaultConfigDir.
+	ConfigFileName = "config.yaml"
+)
+
+// Built-in scorecard test image references used by the "basic" and "olm" suites.
+const (
+	basicTestImage = "quay.io/operator-framework/scorecard-test:latest"
+	olmTestImage   = "quay.io/operator-framework/scorecard-test:latest"
+)
+
+// BuiltinStage returns the built-in scorecard StageConfiguration for suite,
+// for use with 'generate bundle --scorecard-suite'. Supported suites are
+// "basic", "olm", and "custom"; "custom" has no built-in tests and exists so
+// users can request it without generate bundle failing, then populate it
+// themselves via --scorecard-config.
+func BuiltinStage(suite string) (v1alpha3.StageConfiguration, error) {
+	switch suite {
+	case "basic":
+		return basicStage(), nil
+	case "olm":
+		return olmStage(), nil
+	case "custom":
+		return v1alpha3.StageConfiguration{Parallel: true}, nil
+	default:
+		return v1alpha3.StageConfiguration{}, fmt.Errorf("unknown built-in scorecard suite %q, must be one of \"basic\", \"olm\", or \"custom\"", suite)
+	}
+}
+
+// basicStage returns the basic test suite, which checks that the bundle's
+// CRs have a spec block.
+func basicStage() v1alpha3.StageConfiguration {
+	return v1alpha3.StageConfiguration{
+		Parallel: true,
+		Tests: []v1alpha3.TestConfiguration{
+			{
+				Entrypoint: []string{"scorecard-test", "basic-check-spec"},
+				Image:      basicTestImage,
+				Labels:     map[string]string{"suite": "basic", "test": "basic-check-spec-test"},
+			},
+		},
+	}
+}
+
+// olmStage returns the olm test suite, which checks OLM bundle validity and
+// CSV descriptor completeness.
+func olmStage() v1alpha3.StageConfiguration {
+	return v1alpha3.StageConfiguration{
+		Parallel: true,
+		Tests: []v1alpha3.TestConfiguration{
+			{
+				Entrypoint: []string{"scorecard-test", "olm-bundle-validation"},
+				Image:      olmTestImage,
+				Labels:     map[string]string{"suite": "olm", "test": "olm-bundle-validation-test"},
+			},
+			{
+				Entrypoint: []string{"scorecard-test", "olm-crds-have-validation"},
+				Image:      olmTestImage,
+				Labels:     map[string]string{"suite": "olm", "test": "olm-crds-have-validation-test"},
+			},
+			{
+				Entrypoint: []string{"scorecard-test", "olm-spec-descriptors"},
+				Image:      olmTestImage,
+				Labels:     map[string]string{"suite": "olm", "test": "olm-spec-descriptors-test"},
+			},
+			{
+				Entrypoint: []string{"scorecard-test", "olm-status-descriptors"},
+				Image:      olmTestImage,
+				Labels:     map[string]string{"suite": "olm", "test": "olm-status-descriptors-test"},
+			},
+		},
+	}
+}
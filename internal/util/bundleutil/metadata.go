@@ -0,0 +1,143 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundleutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Well-known bundle annotation keys, as defined by operator-registry.
+const (
+	mediaTypeAnnotation      = "operators.operatorframework.io.bundle.mediatype.v1"
+	manifestsDirAnnotation   = "operators.operatorframework.io.bundle.manifests.v1"
+	metadataDirAnnotation    = "operators.operatorframework.io.bundle.metadata.v1"
+	packageAnnotation        = "operators.operatorframework.io.bundle.package.v1"
+	channelsAnnotation       = "operators.operatorframework.io.bundle.channels.v1"
+	defaultChannelAnnotation = "operators.operatorframework.io.bundle.channel.default.v1"
+	manifestsDirValue        = "manifests/"
+	metadataDirValue         = "metadata/"
+	// RegistryV1MediaType is the default bundle format: a CSV plus its
+	// supporting manifests, assembled by OLM into a running operator.
+	RegistryV1MediaType = "registry+v1"
+	// PlainV0MediaType is the plain bundle format: a set of manifests applied
+	// as-is, with no CSV, as expected by OLM v1/operator-controller.
+	PlainV0MediaType = "plain+v0"
+)
+
+// BundleMetaData holds the information needed to generate a bundle's
+// metadata/annotations.yaml and bundle.Dockerfile.
+type BundleMetaData struct {
+	// BundleDir is the root directory of the bundle, containing manifests/ and metadata/.
+	BundleDir string
+	// PackageName is the bundle's package name.
+	PackageName string
+	// Channels are the channels this bundle belongs to.
+	Channels []string
+	// DefaultChannel is this bundle's default channel, and must be one of Channels.
+	DefaultChannel string
+	// OtherLabels are additional annotations/labels to include, e.g. metrics labels.
+	OtherLabels map[string]string
+	// IsScoreConfigPresent indicates whether a scorecard config exists in the bundle.
+	IsScoreConfigPresent bool
+	// MediaType is the bundle format, e.g. "registry+v1" or "plain+v0".
+	// Defaults to RegistryV1MediaType if unset.
+	MediaType string
+}
+
+// annotations returns the full set of bundle annotations described by b.
+func (b BundleMetaData) annotations() map[string]string {
+	mediaType := b.MediaType
+	if mediaType == "" {
+		mediaType = RegistryV1MediaType
+	}
+
+	annotations := map[string]string{
+		mediaTypeAnnotation:      mediaType,
+		manifestsDirAnnotation:   manifestsDirValue,
+		metadataDirAnnotation:    metadataDirValue,
+		packageAnnotation:        b.PackageName,
+		channelsAnnotation:       strings.Join(b.Channels, ","),
+		defaultChannelAnnotation: b.DefaultChannel,
+	}
+	for k, v := range b.OtherLabels {
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// GenerateMetadata writes metadata/annotations.yaml and bundle.Dockerfile to
+// b.BundleDir, describing this bundle's package, channels, and media type.
+func (b BundleMetaData) GenerateMetadata() error {
+	annotations := b.annotations()
+
+	if err := b.writeAnnotationsFile(annotations); err != nil {
+		return fmt.Errorf("error writing bundle metadata: %v", err)
+	}
+	if err := b.writeDockerfile(annotations); err != nil {
+		return fmt.Errorf("error writing bundle.Dockerfile: %v", err)
+	}
+
+	return nil
+}
+
+// writeAnnotationsFile writes annotations to <BundleDir>/metadata/annotations.yaml.
+func (b BundleMetaData) writeAnnotationsFile(annotations map[string]string) error {
+	metadataDir := filepath.Join(b.BundleDir, "metadata")
+	if err := os.MkdirAll(metadataDir, 0755); err != nil {
+		return err
+	}
+
+	content := struct {
+		Annotations map[string]string `json:"annotations"`
+	}{Annotations: annotations}
+
+	out, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(metadataDir, "annotations.yaml"), out, 0666)
+}
+
+// writeDockerfile writes a bundle.Dockerfile to BundleDir that LABELs the
+// image with annotations and copies the bundle's manifests and metadata
+// directories into the image, mirroring annotations.yaml as required by
+// operator-registry/opm.
+func (b BundleMetaData) writeDockerfile(annotations map[string]string) error {
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("FROM scratch\n\n")
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "LABEL %s=%s\n", k, annotations[k])
+	}
+	sb.WriteString("\nCOPY manifests /manifests/\n")
+	sb.WriteString("COPY metadata /metadata/\n")
+	if b.IsScoreConfigPresent {
+		sb.WriteString("COPY tests/scorecard /tests/scorecard/\n")
+	}
+
+	return os.WriteFile(filepath.Join(b.BundleDir, "bundle.Dockerfile"), []byte(sb.String()), 0666)
+}